@@ -2,6 +2,8 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -13,24 +15,32 @@ import (
 const normalizationTableURL = "https://raw.githubusercontent.com/zy9ard3/zy9ard3.github.io/refs/heads/main/normalizations.html"
 
 func main() {
+	maxVariations := flag.Int("max", 0, "maximum number of variations to emit (0 = unlimited)")
+	limitPerChar := flag.Int("limit-per-char", 0, "maximum number of substitution candidates considered per character (0 = unlimited)")
+	timeout := flag.Duration("timeout", 0, "abort generation after this duration (0 = no timeout)")
+	encoding := flag.String("encoding", "raw", "comma-separated wire encodings to emit (raw, percent-utf8, percent-utf16, html-entity, js-unicode, css-escape, all)")
+	flag.Parse()
+
+	encodings, err := charNorm.ParseEncodingSet(*encoding)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing -encoding: %v\n", err)
+		os.Exit(1)
+	}
+
 	// 2b. Call charNorm.ParseNormalizationTable
-	// fmt.Fprintln(os.Stderr, "Fetching and parsing normalization table...") // Debug message
 	normMap, err := charNorm.ParseNormalizationTable(normalizationTableURL)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error parsing normalization table: %v\n", err)
 		os.Exit(1)
 	}
-	// fmt.Fprintln(os.Stderr, "Normalization table parsed successfully.") // Debug message
 
 	// 2c. Determine the input payload
 	var inputPayload string
-	if len(os.Args) > 1 {
+	if flag.NArg() > 0 {
 		// 2c.i. Command-line arguments provided
-		inputPayload = strings.Join(os.Args[1:], " ")
-		// fmt.Fprintf(os.Stderr, "Using input from command-line arguments: \"%s\"\n", inputPayload) // Debug message
+		inputPayload = strings.Join(flag.Args(), " ")
 	} else {
 		// 2c.ii. No command-line arguments, read from stdin
-		// fmt.Fprintln(os.Stderr, "No command-line arguments provided. Reading from stdin. Press Ctrl+D (or Ctrl+Z on Windows) to end input.") // User guidance
 		reader := bufio.NewReader(os.Stdin)
 		var sb strings.Builder
 		// Read line by line as Stdin might not be closed by the user immediately
@@ -46,22 +56,27 @@ func main() {
 			}
 		}
 		inputPayload = strings.TrimSpace(sb.String())
-		// fmt.Fprintf(os.Stderr, "Using input from stdin: \"%s\"\n", inputPayload) // Debug message
 	}
 
-	if inputPayload == "" && len(os.Args) <=1 { // only if no args AND stdin was empty
-		// fmt.Fprintln(os.Stderr, "No input provided via arguments or stdin. Nothing to process.") // Info message
-		// As GenerateVariations handles empty string to return {""}, let it proceed.
-		// If an error is desired for no input, exit here:
-		// os.Exit(0) // or 1 depending on desired behavior
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
 	}
 
+	opts := charNorm.Options{
+		MaxVariations: *maxVariations,
+		MaxPerRune:    *limitPerChar,
+		Context:       ctx,
+	}
 
-	// 2d. Call charNorm.GenerateVariations
-	variations := charNorm.GenerateVariations(inputPayload, normMap)
-
-	// 2e. Print each variation
-	for _, variation := range variations {
-		fmt.Println(variation)
+	// 2d-2e. Stream variations to stdout as they're generated, rather than
+	// materializing them all in memory first, rendering each in every
+	// requested wire encoding.
+	for variation := range charNorm.GenerateVariationsIter(inputPayload, normMap, opts) {
+		for _, encoded := range charNorm.EncodeString(variation, encodings) {
+			fmt.Println(encoded)
+		}
 	}
 }
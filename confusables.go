@@ -0,0 +1,141 @@
+package charNorm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadConfusables builds a normalization table from the Unicode Consortium's
+// confusables data (UTS #39 confusables.txt), read from r. Each data line has
+// the form:
+//
+//	SOURCE ; TARGET ; MA  # comment
+//
+// where SOURCE and TARGET are space-separated hex codepoints and MA marks a
+// confusable between scripts ("prototype" confusables) and within a script
+// ("MA" = both). Lines are grouped by TARGET: only targets that decode to a
+// single ASCII rune are kept, and every single-rune SOURCE (other than the
+// target itself) that maps to that target is collected as one of its
+// confusable variants; multi-rune sources are skipped - i.e. the table is inverted
+// relative to the source file, mapping the ASCII skeleton to the set of
+// Unicode runes that are visually confusable with it.
+func LoadConfusables(r io.Reader) (map[rune][]rune, error) {
+	normalizationMap := make(map[rune][]rune)
+	seen := make(map[rune]map[rune]bool)
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			continue
+		}
+
+		source, err := decodeCodepoints(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid source codepoints: %w", lineNo, err)
+		}
+		target, err := decodeCodepoints(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid target codepoints: %w", lineNo, err)
+		}
+
+		// Only single-rune ASCII targets are useful as a normalization
+		// "skeleton"; multi-rune targets and non-ASCII targets are skipped.
+		if len(target) != 1 || target[0] > 127 {
+			continue
+		}
+		if len(source) != 1 {
+			continue
+		}
+		ascii, variant := target[0], source[0]
+		if ascii == variant {
+			continue
+		}
+
+		if seen[ascii] == nil {
+			seen[ascii] = make(map[rune]bool)
+		}
+		if !seen[ascii][variant] {
+			seen[ascii][variant] = true
+			normalizationMap[ascii] = append(normalizationMap[ascii], variant)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read confusables data: %w", err)
+	}
+
+	return normalizationMap, nil
+}
+
+// decodeCodepoints parses a space-separated list of hex codepoints (as found
+// on either side of a confusables.txt data line) into runes.
+func decodeCodepoints(field string) ([]rune, error) {
+	fields := strings.Fields(field)
+	runes := make([]rune, 0, len(fields))
+	for _, f := range fields {
+		cp, err := strconv.ParseInt(f, 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", f, err)
+		}
+		runes = append(runes, rune(cp))
+	}
+	return runes, nil
+}
+
+// Merge combines any number of normalization tables into one, unioning the
+// variant list for each original rune across all inputs. Variants are
+// deduplicated but otherwise kept in the order they're first encountered,
+// scanning tables in the order given.
+func Merge(tables ...map[rune][]rune) map[rune][]rune {
+	merged := make(map[rune][]rune)
+	seen := make(map[rune]map[rune]bool)
+
+	for _, table := range tables {
+		for original, variants := range table {
+			if seen[original] == nil {
+				seen[original] = make(map[rune]bool)
+			}
+			for _, v := range variants {
+				if !seen[original][v] {
+					seen[original][v] = true
+					merged[original] = append(merged[original], v)
+				}
+			}
+		}
+	}
+
+	return merged
+}
+
+// Filter returns a copy of table with every (original, variant) pair for
+// which keep returns false removed. Original runes left with no remaining
+// variants are dropped entirely.
+func Filter(table map[rune][]rune, keep func(original, variant rune) bool) map[rune][]rune {
+	filtered := make(map[rune][]rune, len(table))
+	for original, variants := range table {
+		var kept []rune
+		for _, v := range variants {
+			if keep(original, v) {
+				kept = append(kept, v)
+			}
+		}
+		if len(kept) > 0 {
+			filtered[original] = kept
+		}
+	}
+	return filtered
+}
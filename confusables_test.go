@@ -0,0 +1,68 @@
+package charNorm
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleConfusables = `# comment line, should be ignored
+0041 ; 0041 ; MA # ( A → A ) LATIN CAPITAL LETTER A
+0391 ; 0041 ; MA # ( Α → A ) GREEK CAPITAL LETTER ALPHA
+0410 ; 0041 ; MA # ( А → A ) CYRILLIC CAPITAL LETTER A
+0042 0043 ; 0042 ; MA # multi-rune source is skipped
+0044 ; 0044 0045 ; MA # multi-rune target is skipped
+
+0415 ; 0045 ; MA # ( Е → E ) CYRILLIC CAPITAL LETTER IE
+`
+
+func TestLoadConfusables(t *testing.T) {
+	table, err := LoadConfusables(strings.NewReader(sampleConfusables))
+	if err != nil {
+		t.Fatalf("LoadConfusables: %v", err)
+	}
+
+	want := []rune{0x0391, 0x0410}
+	if !runesEqual(table['A'], want) {
+		t.Errorf("table['A'] = %q, want %q", string(table['A']), string(want))
+	}
+	if got, want := table['E'], []rune{0x0415}; !runesEqual(got, want) {
+		t.Errorf("table['E'] = %q, want %q", string(got), string(want))
+	}
+	if _, ok := table['B']; ok {
+		t.Errorf("table['B'] should be absent (multi-rune source line skipped)")
+	}
+	if _, ok := table['D']; ok {
+		t.Errorf("table['D'] should be absent (multi-rune target line skipped)")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := map[rune][]rune{'A': []rune{'À', 'Á'}}
+	b := map[rune][]rune{'A': []rune{'Á', 'Â'}, 'B': []rune{'Ɓ'}}
+
+	merged := Merge(a, b)
+	if got, want := merged['A'], []rune("ÀÁÂ"); !runesEqual(got, want) {
+		t.Errorf("merged['A'] = %q, want %q (deduped union in first-seen order)", string(got), string(want))
+	}
+	if got, want := merged['B'], []rune("Ɓ"); !runesEqual(got, want) {
+		t.Errorf("merged['B'] = %q, want %q", string(got), string(want))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	table := map[rune][]rune{
+		'A': []rune("ÀÁ"),
+		'B': []rune("Ɓ"),
+	}
+
+	filtered := Filter(table, func(original, variant rune) bool {
+		return variant != 'Ɓ'
+	})
+
+	if got, want := filtered['A'], []rune("ÀÁ"); !runesEqual(got, want) {
+		t.Errorf("filtered['A'] = %q, want %q", string(got), string(want))
+	}
+	if _, ok := filtered['B']; ok {
+		t.Errorf("filtered['B'] should be dropped once its only variant is filtered out")
+	}
+}
@@ -0,0 +1,78 @@
+package charNorm
+
+import "strings"
+
+// Finding describes one confusable rune located by Detector.Scan.
+type Finding struct {
+	// Index is the rune offset of the finding within the scanned string.
+	Index int
+	// Rune is the confusable rune that was found.
+	Rune rune
+	// CanonicalASCII is the ASCII character Rune is confusable with.
+	CanonicalASCII rune
+}
+
+// Detector scans arbitrary UTF-8 input for runes that are confusable
+// variants of ASCII characters, using a normalization map as produced by
+// LoadNormalizationTable or LoadConfusables. It's the inverse of the
+// variation generators: where those turn ASCII into look-alikes, Detector
+// turns look-alikes back into their ASCII skeleton, which is useful for
+// defensive uses like log analysis or username-squatting detection.
+type Detector struct {
+	skeleton map[rune]rune
+}
+
+// NewDetector builds a Detector from a normalization map (original ASCII
+// rune -> confusable variants). If the same variant rune appears under more
+// than one original, the first one encountered wins; map iteration order is
+// randomized, so callers relying on that case should pre-Filter the table to
+// remove the ambiguity.
+func NewDetector(table map[rune][]rune) *Detector {
+	skeleton := make(map[rune]rune)
+	for original, variants := range table {
+		for _, v := range variants {
+			if _, exists := skeleton[v]; !exists {
+				skeleton[v] = original
+			}
+		}
+	}
+	return &Detector{skeleton: skeleton}
+}
+
+// Skeletonize returns s with every confusable rune replaced by its canonical
+// ASCII equivalent, per UTS #39's skeleton algorithm. Runes with no entry in
+// the Detector's table, including ASCII characters themselves, pass through
+// unchanged.
+func (d *Detector) Skeletonize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if ascii, ok := d.skeleton[r]; ok {
+			b.WriteRune(ascii)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Scan reports every confusable rune found in s, in order of appearance,
+// with Finding.Index counting runes rather than bytes.
+func (d *Detector) Scan(s string) []Finding {
+	var findings []Finding
+	idx := 0
+	for _, r := range s {
+		if ascii, ok := d.skeleton[r]; ok {
+			findings = append(findings, Finding{Index: idx, Rune: r, CanonicalASCII: ascii})
+		}
+		idx++
+	}
+	return findings
+}
+
+// SkeletonEqual reports whether a and b normalize to the same ASCII
+// skeleton - the standard UTS #39 confusability test, e.g. for catching
+// username-squatting with look-alike characters.
+func (d *Detector) SkeletonEqual(a, b string) bool {
+	return d.Skeletonize(a) == d.Skeletonize(b)
+}
@@ -0,0 +1,38 @@
+package charNorm
+
+import "testing"
+
+func TestDetectorSkeletonize(t *testing.T) {
+	table := map[rune][]rune{'A': []rune("À"), 'O': []rune("Ο")} // Greek Omicron confusable with O
+	d := NewDetector(table)
+
+	if got, want := d.Skeletonize("PÀYPΟL"), "PAYPOL"; got != want {
+		t.Errorf("Skeletonize = %q, want %q", got, want)
+	}
+}
+
+func TestDetectorScan(t *testing.T) {
+	table := map[rune][]rune{'A': []rune("À")}
+	d := NewDetector(table)
+
+	findings := d.Scan("xÀy")
+	if len(findings) != 1 {
+		t.Fatalf("Scan returned %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if f.Index != 1 || f.Rune != 'À' || f.CanonicalASCII != 'A' {
+		t.Errorf("Scan finding = %+v, want {Index:1 Rune:À CanonicalASCII:A}", f)
+	}
+}
+
+func TestDetectorSkeletonEqual(t *testing.T) {
+	table := map[rune][]rune{'A': []rune("À")}
+	d := NewDetector(table)
+
+	if !d.SkeletonEqual("PAYPAL", "PÀYPAL") {
+		t.Error("expected PAYPAL and PÀYPAL to share a skeleton")
+	}
+	if d.SkeletonEqual("PAYPAL", "PAYPALX") {
+		t.Error("expected PAYPAL and PAYPALX to have different skeletons")
+	}
+}
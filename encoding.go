@@ -0,0 +1,203 @@
+package charNorm
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// EncodingSet is a bitmask selecting which wire encodings
+// GenerateEncodedVariations and EncodeString should produce for a string.
+type EncodingSet uint
+
+const (
+	// EncodingRaw passes the string through unmodified (UTF-8).
+	EncodingRaw EncodingSet = 1 << iota
+	// EncodingPercentUTF8 percent-encodes each UTF-8 byte, e.g. "%E2%88%95".
+	EncodingPercentUTF8
+	// EncodingPercentUTF16 percent-encodes each big-endian UTF-16 code unit,
+	// plus overlong UTF-8 byte sequences for codepoints that admit one -
+	// both classic encoding-layer WAF bypass tricks.
+	EncodingPercentUTF16
+	// EncodingHTMLEntity renders each rune as a decimal HTML numeric
+	// character reference, e.g. "&#8725;".
+	EncodingHTMLEntity
+	// EncodingJSUnicode renders each rune as a JavaScript \uXXXX escape (or
+	// \u{XXXXX} for codepoints outside the BMP).
+	EncodingJSUnicode
+	// EncodingCSSEscape renders each rune as a CSS hex escape followed by a
+	// space, e.g. "\2215 ".
+	EncodingCSSEscape
+
+	// EncodingAll selects every encoding above.
+	EncodingAll = EncodingRaw | EncodingPercentUTF8 | EncodingPercentUTF16 |
+		EncodingHTMLEntity | EncodingJSUnicode | EncodingCSSEscape
+)
+
+var encodingNames = map[string]EncodingSet{
+	"raw":           EncodingRaw,
+	"percent-utf8":  EncodingPercentUTF8,
+	"percent-utf16": EncodingPercentUTF16,
+	"html-entity":   EncodingHTMLEntity,
+	"js-unicode":    EncodingJSUnicode,
+	"css-escape":    EncodingCSSEscape,
+	"all":           EncodingAll,
+}
+
+// ParseEncodingSet parses a comma-separated list of encoding names (raw,
+// percent-utf8, percent-utf16, html-entity, js-unicode, css-escape, or all)
+// into an EncodingSet.
+func ParseEncodingSet(s string) (EncodingSet, error) {
+	var set EncodingSet
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		enc, ok := encodingNames[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown encoding %q", name)
+		}
+		set |= enc
+	}
+	return set, nil
+}
+
+// GenerateEncodedVariations generates every variation of input (as
+// GenerateVariationsIter does, bounded by opts) and renders each one in
+// every wire encoding selected by encodings, producing a fuzz corpus
+// spanning both character substitution and encoding-layer tricks. Callers
+// generating from large inputs should set opts.MaxVariations and/or
+// opts.MaxPerRune, same as for GenerateVariationsIter, since this still
+// materializes the full encoded result in memory.
+func GenerateEncodedVariations(input string, m map[rune][]rune, encodings EncodingSet, opts Options) []string {
+	var out []string
+	for v := range GenerateVariationsIter(input, m, opts) {
+		out = append(out, EncodeString(v, encodings)...)
+	}
+	return out
+}
+
+// EncodeString renders s in each of the wire encodings selected by encodings,
+// in a fixed order (raw, percent-UTF-8, percent-UTF-16, HTML entity, JS
+// unicode, CSS escape). Each encoding contributes one string, except
+// percent-UTF-16, which is followed by the overlong UTF-8 variants of s (up
+// to three more strings).
+func EncodeString(s string, encodings EncodingSet) []string {
+	var out []string
+	if encodings&EncodingRaw != 0 {
+		out = append(out, s)
+	}
+	if encodings&EncodingPercentUTF8 != 0 {
+		out = append(out, percentEncodeUTF8(s))
+	}
+	if encodings&EncodingPercentUTF16 != 0 {
+		out = append(out, percentEncodeUTF16(s))
+		out = append(out, overlongUTF8Variants(s)...)
+	}
+	if encodings&EncodingHTMLEntity != 0 {
+		out = append(out, htmlEntityEncode(s))
+	}
+	if encodings&EncodingJSUnicode != 0 {
+		out = append(out, jsUnicodeEncode(s))
+	}
+	if encodings&EncodingCSSEscape != 0 {
+		out = append(out, cssEscapeEncode(s))
+	}
+	return out
+}
+
+func percentEncodeUTF8(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		fmt.Fprintf(&b, "%%%02X", s[i])
+	}
+	return b.String()
+}
+
+func percentEncodeUTF16(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		for _, unit := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(&b, "%%%02X%%%02X", byte(unit>>8), byte(unit))
+		}
+	}
+	return b.String()
+}
+
+// overlongUTF8Variants returns up to three percent-encoded copies of s, one
+// per width (2, 3, and 4 bytes), in which every rune that fits in 7 bits is
+// re-encoded as an overlong UTF-8 sequence of that width instead of the
+// canonical single byte - a classic normalization-bypass trick that some
+// decoders accept despite being invalid UTF-8. It returns nil if s has no
+// such rune.
+func overlongUTF8Variants(s string) []string {
+	var variants []string
+	for width := 2; width <= 4; width++ {
+		var b strings.Builder
+		any := false
+		for _, r := range s {
+			if r > 0x7F {
+				// Overlong re-encoding is only meaningful for codepoints
+				// that have a canonical single-byte form; pass others
+				// through using their normal UTF-8 percent encoding.
+				b.WriteString(percentEncodeUTF8(string(r)))
+				continue
+			}
+			any = true
+			for _, bb := range overlongBytes(r, width) {
+				fmt.Fprintf(&b, "%%%02X", bb)
+			}
+		}
+		if any {
+			variants = append(variants, b.String())
+		}
+	}
+	return variants
+}
+
+// overlongBytes renders the codepoint r (r <= 0x7F) as a non-canonical
+// UTF-8 sequence of exactly width bytes (2, 3, or 4) by spreading its bits
+// across the continuation-byte pattern instead of using the minimal
+// single-byte form.
+func overlongBytes(r rune, width int) []byte {
+	bits := uint32(r) // <= 7 bits of payload
+	switch width {
+	case 2:
+		return []byte{0xC0 | byte(bits>>6), 0x80 | byte(bits&0x3F)}
+	case 3:
+		return []byte{0xE0, 0x80 | byte(bits>>6), 0x80 | byte(bits&0x3F)}
+	case 4:
+		return []byte{0xF0, 0x80, 0x80 | byte(bits>>6), 0x80 | byte(bits&0x3F)}
+	default:
+		return []byte{byte(bits)}
+	}
+}
+
+func htmlEntityEncode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		fmt.Fprintf(&b, "&#%d;", r)
+	}
+	return b.String()
+}
+
+func jsUnicodeEncode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 0xFFFF {
+			fmt.Fprintf(&b, "\\u{%X}", r)
+		} else {
+			fmt.Fprintf(&b, "\\u%04X", r)
+		}
+	}
+	return b.String()
+}
+
+func cssEscapeEncode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		fmt.Fprintf(&b, "\\%X ", r)
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package charNorm
+
+import "testing"
+
+func TestParseEncodingSet(t *testing.T) {
+	set, err := ParseEncodingSet("percent-utf8, html-entity")
+	if err != nil {
+		t.Fatalf("ParseEncodingSet: %v", err)
+	}
+	if set&EncodingPercentUTF8 == 0 || set&EncodingHTMLEntity == 0 {
+		t.Errorf("ParseEncodingSet did not set the expected bits: %v", set)
+	}
+	if set&EncodingRaw != 0 {
+		t.Errorf("ParseEncodingSet set EncodingRaw unexpectedly: %v", set)
+	}
+}
+
+func TestParseEncodingSetUnknown(t *testing.T) {
+	if _, err := ParseEncodingSet("not-a-real-encoding"); err == nil {
+		t.Fatal("expected error for unknown encoding name")
+	}
+}
+
+func TestEncodeStringPercentUTF8(t *testing.T) {
+	got := EncodeString("A", EncodingPercentUTF8)
+	want := []string{"%41"}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("EncodeString = %v, want %v", got, want)
+	}
+}
+
+func TestEncodeStringHTMLEntity(t *testing.T) {
+	got := EncodeString("A", EncodingHTMLEntity)
+	want := "&#65;"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("EncodeString = %v, want [%q]", got, want)
+	}
+}
+
+func TestEncodeStringJSUnicode(t *testing.T) {
+	got := EncodeString("A", EncodingJSUnicode)
+	want := "\\u0041"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("EncodeString = %v, want [%q]", got, want)
+	}
+}
+
+func TestEncodeStringCSSEscape(t *testing.T) {
+	got := EncodeString("/", EncodingCSSEscape)
+	want := "\\2F "
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("EncodeString = %v, want [%q]", got, want)
+	}
+}
+
+func TestOverlongUTF8Variants(t *testing.T) {
+	variants := overlongUTF8Variants("/")
+	if len(variants) != 3 {
+		t.Fatalf("overlongUTF8Variants(\"/\") returned %d variants, want 3 (2, 3, and 4-byte forms)", len(variants))
+	}
+	// The classic 2-byte overlong encoding of '/' (0x2F) is %C0%AF.
+	if variants[0] != "%C0%AF" {
+		t.Errorf("2-byte overlong form = %q, want %q", variants[0], "%C0%AF")
+	}
+}
+
+func TestGenerateEncodedVariationsRespectsOptions(t *testing.T) {
+	m := map[rune][]rune{'A': []rune("ÀÁÂÃ")}
+	opts := Options{MaxVariations: 2}
+	got := GenerateEncodedVariations("AAAA", m, EncodingRaw, opts)
+	if len(got) != 2 {
+		t.Errorf("GenerateEncodedVariations produced %d results, want 2 (bounded by MaxVariations)", len(got))
+	}
+}
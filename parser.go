@@ -1,185 +1,365 @@
 package charNorm
 
 import (
+	"errors"
 	"fmt"
-	"net/http"
+	"io"
+	"strconv"
 	"strings"
+
 	"golang.org/x/net/html"
-	"errors"
 )
 
+// TableSelector configures which <table> in an HTML document to parse and
+// how to read its rows and columns into a normalization map.
+type TableSelector struct {
+	// ID, if non-empty, restricts matches to a table with this id="" attribute.
+	ID string
+	// Class, if non-empty, restricts matches to a table with this class.
+	Class string
+	// CaptionContains, if non-empty, restricts matches to a table whose
+	// <caption> contains this substring (case-insensitive).
+	CaptionContains string
+	// Ordinal selects the Nth matching table (0-based). Defaults to 0, i.e.
+	// the first table satisfying ID/Class/CaptionContains, or the first
+	// table in the document if none of those are set.
+	Ordinal int
+
+	// HeaderRow is the 0-based row holding the original ASCII characters.
+	HeaderRow int
+	// DataRowRange is the inclusive [start, end] 0-based row range holding
+	// normalization data. end == -1 means "to the last row in the table".
+	DataRowRange [2]int
+	// Transposed indicates the table lists one original character per row
+	// (in column HeaderRow) rather than one per column (in row HeaderRow).
+	Transposed bool
+}
+
+// DefaultTableSelector reproduces the layout of the original hard-coded
+// parser: the first table in the document, row 0 holding hex codes
+// (skipped), row 1 holding the original ASCII characters, and rows 2-61
+// holding their normalizations. Unlike the original parser, a table with
+// fewer rows than this is not an error - it's parsed as far as it goes, and
+// TableStats.DataRowsFound reports how many data rows were actually there.
+var DefaultTableSelector = TableSelector{
+	HeaderRow:    1,
+	DataRowRange: [2]int{2, 61},
+}
+
+// TableStats records what LoadHTMLTable actually found in the
+// document, so callers can notice a source page that no longer matches their
+// TableSelector without treating a short or reshaped table as a hard error.
+type TableStats struct {
+	TablesInDocument int
+	HeaderColumns    int
+	DataRowsFound    int
+}
+
 // ParseNormalizationTable fetches an HTML table from a URL and parses it
-// to create a map of ASCII characters to their Unicode normalizations.
+// to create a map of ASCII characters to their Unicode normalizations,
+// using DefaultTableSelector.
+//
+// This is a thin convenience wrapper around LoadNormalizationTable kept for
+// backward compatibility; new callers should construct a Source (HTTPSource,
+// FileSource, ReaderSource, EmbeddedSource, ...) and call
+// LoadNormalizationTable or, for control over table layout, LoadHTMLTable
+// directly.
 func ParseNormalizationTable(url string) (map[rune][]rune, error) {
 	if url == "" {
 		return nil, errors.New("url cannot be empty")
 	}
+	return LoadNormalizationTable(&HTTPSource{URL: url})
+}
 
-	resp, err := http.Get(url)
+// LoadHTMLTable parses an HTML document read from r according to sel and
+// returns the resulting normalization map along with stats describing what
+// was actually found. Unlike the original parser, a table shorter than
+// sel.DataRowRange (or with fewer header columns than expected) is not an
+// error: it's parsed as far as it goes, and TableStats reports what was
+// found so the caller can decide whether that's good enough.
+func LoadHTMLTable(r io.Reader, sel TableSelector) (map[rune][]rune, TableStats, error) {
+	doc, err := html.Parse(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch URL %s: %w", url, err)
+		return nil, TableStats{}, fmt.Errorf("failed to parse HTML: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch URL %s: status code %d", url, resp.StatusCode)
+	tables := findTables(doc)
+	stats := TableStats{TablesInDocument: len(tables)}
+	if len(tables) == 0 {
+		return nil, stats, errors.New("no table found in HTML")
 	}
 
-	doc, err := html.Parse(resp.Body)
+	table, err := selectTable(tables, sel)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML from %s: %w", url, err)
+		return nil, stats, err
 	}
 
+	grid := buildTableGrid(table)
+	if sel.Transposed {
+		grid = transposeGrid(grid)
+	}
+
+	if sel.HeaderRow < 0 || sel.HeaderRow >= len(grid) {
+		return nil, stats, fmt.Errorf("header row %d out of range (table has %d rows)", sel.HeaderRow, len(grid))
+	}
+	headerRow := grid[sel.HeaderRow]
+	stats.HeaderColumns = len(headerRow)
+
+	// originalChars[i] is the ASCII character for column i, or 0 for columns
+	// with no (or empty) header cell.
+	originalChars := make([]rune, len(headerRow))
 	normalizationMap := make(map[rune][]rune)
-	var originalChars []rune
-	var tableFound bool
-	var rowCount int
+	for i, cell := range headerRow {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+		original := []rune(cell)[0]
+		originalChars[i] = original
+		if _, ok := normalizationMap[original]; !ok {
+			normalizationMap[original] = []rune{}
+		}
+	}
+
+	start, end := sel.DataRowRange[0], sel.DataRowRange[1]
+	if end < 0 || end >= len(grid) {
+		end = len(grid) - 1
+	}
+
+	seen := make(map[rune]map[rune]bool)
+	for rowIdx := start; rowIdx >= 0 && rowIdx <= end && rowIdx < len(grid); rowIdx++ {
+		stats.DataRowsFound++
+		row := grid[rowIdx]
+		for col, cell := range row {
+			if col >= len(originalChars) || originalChars[col] == 0 {
+				continue
+			}
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			original := originalChars[col]
+			normRune := []rune(cell)[0]
+
+			if seen[original] == nil {
+				seen[original] = make(map[rune]bool)
+			}
+			if !seen[original][normRune] {
+				seen[original][normRune] = true
+				normalizationMap[original] = append(normalizationMap[original], normRune)
+			}
+		}
+	}
+
+	return normalizationMap, stats, nil
+}
+
+// parseHTMLTable parses an HTML document read from r using
+// DefaultTableSelector. It's the entry point LoadNormalizationTable uses
+// when it sniffs a source as HTML.
+func parseHTMLTable(r io.Reader) (map[rune][]rune, error) {
+	table, _, err := LoadHTMLTable(r, DefaultTableSelector)
+	return table, err
+}
 
-	// findTableAndParse is a recursive function to find the table and parse it.
-	var findTableAndParse func(*html.Node)
-	findTableAndParse = func(n *html.Node) {
+// findTables returns every <table> element in the document, in document
+// order, including nested tables.
+func findTables(doc *html.Node) []*html.Node {
+	var tables []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
 		if n.Type == html.ElementNode && n.Data == "table" {
-			tableFound = true
-			// Traverse rows of the table
-			for tr := n.FirstChild; tr != nil; tr = tr.NextSibling {
-				if tr.Type == html.ElementNode && tr.Data == "tbody" { // HTML often implicitly adds tbody
-					for r := tr.FirstChild; r != nil; r = r.NextSibling {
-						if r.Type == html.ElementNode && r.Data == "tr" {
-							rowCount++
-							var currentCellIndex int
-							if rowCount == 1 { // Skip first row (hex codes)
-								continue
-							} else if rowCount == 2 { // Second row: original ASCII characters
-								for td := r.FirstChild; td != nil; td = td.NextSibling {
-									if td.Type == html.ElementNode && td.Data == "td" {
-										charStr := strings.TrimSpace(extractText(td))
-										if len(charStr) > 0 {
-											// Assuming single rune per cell for original chars
-											originalChars = append(originalChars, []rune(charStr)[0])
-											normalizationMap[[]rune(charStr)[0]] = []rune{} // Initialize slice
-										} else {
-											originalChars = append(originalChars, ' ') // Placeholder for empty cells to maintain index
-										}
-									}
-								}
-							} else if rowCount >= 3 && rowCount <= 62 { // Data rows (3rd to 62nd)
-								for td := r.FirstChild; td != nil; td = td.NextSibling {
-									if td.Type == html.ElementNode && td.Data == "td" {
-										if currentCellIndex < len(originalChars) {
-											normCharStr := strings.TrimSpace(extractText(td))
-											if len(normCharStr) > 0 {
-												normRune := []rune(normCharStr)[0]
-												originalChar := originalChars[currentCellIndex]
-												
-												// Add if not already present
-												found := false
-												for _, existingRune := range normalizationMap[originalChar] {
-													if existingRune == normRune {
-														found = true
-														break
-													}
-												}
-												if !found {
-													normalizationMap[originalChar] = append(normalizationMap[originalChar], normRune)
-												}
-											}
-										}
-										currentCellIndex++
-									}
-								}
-							}
-						}
-					}
-				} else if tr.Type == html.ElementNode && tr.Data == "tr" { // Handle tables without explicit tbody
-					rowCount++
-					var currentCellIndex int
-					if rowCount == 1 { // Skip first row (hex codes)
-						continue
-					} else if rowCount == 2 { // Second row: original ASCII characters
-						for td := tr.FirstChild; td != nil; td = td.NextSibling {
-							if td.Type == html.ElementNode && td.Data == "td" {
-								charStr := strings.TrimSpace(extractText(td))
-								if len(charStr) > 0 {
-									originalChars = append(originalChars, []rune(charStr)[0])
-									normalizationMap[[]rune(charStr)[0]] = []rune{} 
-								} else {
-									originalChars = append(originalChars, ' ') 
-								}
-							}
-						}
-					} else if rowCount >= 3 && rowCount <= 62 { // Data rows
-						for td := tr.FirstChild; td != nil; td = td.NextSibling {
-							if td.Type == html.ElementNode && td.Data == "td" {
-								if currentCellIndex < len(originalChars) {
-									normCharStr := strings.TrimSpace(extractText(td))
-									if len(normCharStr) > 0 {
-										normRune := []rune(normCharStr)[0]
-										originalChar := originalChars[currentCellIndex]
-										
-										found := false
-										for _, existingRune := range normalizationMap[originalChar] {
-											if existingRune == normRune {
-												found = true
-												break
-											}
-										}
-										if !found {
-											normalizationMap[originalChar] = append(normalizationMap[originalChar], normRune)
-										}
-									}
-								}
-								currentCellIndex++
-							}
-						}
-					}
+			tables = append(tables, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return tables
+}
+
+// selectTable narrows tables down to those matching sel's ID/Class/
+// CaptionContains (if any are set) and returns the sel.Ordinal'th match.
+func selectTable(tables []*html.Node, sel TableSelector) (*html.Node, error) {
+	matches := tables
+	if sel.ID != "" || sel.Class != "" || sel.CaptionContains != "" {
+		matches = nil
+		for _, t := range tables {
+			if sel.ID != "" && attrValue(t, "id") != sel.ID {
+				continue
+			}
+			if sel.Class != "" && !hasClass(t, sel.Class) {
+				continue
+			}
+			if sel.CaptionContains != "" && !tableHasCaption(t, sel.CaptionContains) {
+				continue
+			}
+			matches = append(matches, t)
+		}
+	}
+
+	if sel.Ordinal < 0 || sel.Ordinal >= len(matches) {
+		return nil, fmt.Errorf("no table matches selector (found %d candidate table(s), wanted ordinal %d)", len(matches), sel.Ordinal)
+	}
+	return matches[sel.Ordinal], nil
+}
+
+// buildTableGrid resolves a <table> element (including <th> cells and
+// colspan/rowspan) into a dense 2D grid of cell text, one row per <tr>.
+// Rows belonging to a nested <table> are not included.
+func buildTableGrid(table *html.Node) [][]string {
+	var rows []*html.Node
+	collectRows(table, &rows)
+
+	type pendingSpan struct {
+		remaining int
+		value     string
+	}
+	pending := make(map[int]*pendingSpan)
+
+	var grid [][]string
+	for _, tr := range rows {
+		var rowCells []string
+		col := 0
+
+		fillPending := func() {
+			for {
+				p, ok := pending[col]
+				if !ok || p.remaining <= 0 {
+					break
 				}
+				rowCells = append(rowCells, p.value)
+				p.remaining--
+				if p.remaining == 0 {
+					delete(pending, col)
+				}
+				col++
 			}
-			return // Stop searching after finding and processing the first table
 		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			if tableFound { // If table is processed, no need to traverse further
-				return
+
+		fillPending()
+		for td := tr.FirstChild; td != nil; td = td.NextSibling {
+			if td.Type != html.ElementNode || (td.Data != "td" && td.Data != "th") {
+				continue
+			}
+			text := strings.TrimSpace(extractText(td))
+			colspan := attrInt(td, "colspan", 1)
+			rowspan := attrInt(td, "rowspan", 1)
+
+			for i := 0; i < colspan; i++ {
+				rowCells = append(rowCells, text)
+				if rowspan > 1 {
+					pending[col] = &pendingSpan{remaining: rowspan - 1, value: text}
+				}
+				col++
+				fillPending()
 			}
-			findTableAndParse(c)
 		}
+
+		grid = append(grid, rowCells)
 	}
 
-	// extractText recursively extracts all text from a node and its children.
-	var extractText func(*html.Node) string
-	extractText = func(n *html.Node) string {
-		if n.Type == html.TextNode {
-			return n.Data
+	return grid
+}
+
+// collectRows appends every <tr> descendant of n to rows, in document
+// order, without descending into nested <table> elements.
+func collectRows(n *html.Node, rows *[]*html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "table" {
+			continue
 		}
-		if n.Type != html.ElementNode {
-			return ""
+		if c.Type == html.ElementNode && c.Data == "tr" {
+			*rows = append(*rows, c)
+			continue
 		}
-		var ret string
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			ret += extractText(c)
+		collectRows(c, rows)
+	}
+}
+
+// transposeGrid swaps rows and columns, for tables that list one original
+// character per row instead of per column.
+func transposeGrid(grid [][]string) [][]string {
+	if len(grid) == 0 {
+		return grid
+	}
+	maxCols := 0
+	for _, row := range grid {
+		if len(row) > maxCols {
+			maxCols = len(row)
 		}
-		return ret
 	}
 
-	findTableAndParse(doc)
+	transposed := make([][]string, maxCols)
+	for c := 0; c < maxCols; c++ {
+		transposed[c] = make([]string, len(grid))
+		for r, row := range grid {
+			if c < len(row) {
+				transposed[c][r] = row[c]
+			}
+		}
+	}
+	return transposed
+}
 
-	if !tableFound {
-		return nil, errors.New("no table found in HTML")
+// extractText recursively extracts all text from a node and its children,
+// stopping at a nested <table> boundary rather than descending into it - a
+// nested table's text is not folded into the outer cell's text, and its
+// cells are not part of the outer table's grid either.
+func extractText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	if n.Type != html.ElementNode {
+		return ""
+	}
+	if n.Data == "table" {
+		return ""
 	}
-	if rowCount < 62 {
-		// It's possible some tables might be shorter, but the spec implies 62 rows are key.
-		// Depending on strictness, this could be an error or a warning.
-		// For now, returning an error if not enough rows for the full specified normalization data.
-		return nil, fmt.Errorf("table found, but did not contain enough rows (expected at least 62, got %d)", rowCount)
+	var ret string
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		ret += extractText(c)
 	}
-	
-	// Remove any original characters that ended up with no normalizations
-	// (e.g. if they were placeholders for empty cells in the second row)
-	for key, val := range normalizationMap {
-		if key == ' ' && len(val) == 0 { // Check for placeholder space character
-			delete(normalizationMap, key)
+	return ret
+}
+
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
 		}
 	}
+	return ""
+}
+
+func attrInt(n *html.Node, key string, def int) int {
+	v := attrValue(n, key)
+	if v == "" {
+		return def
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 1 {
+		return def
+	}
+	return i
+}
 
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attrValue(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
 
-	return normalizationMap, nil
+func tableHasCaption(table *html.Node, substr string) bool {
+	substr = strings.ToLower(substr)
+	for c := table.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "caption" {
+			return strings.Contains(strings.ToLower(extractText(c)), substr)
+		}
+	}
+	return false
 }
@@ -0,0 +1,184 @@
+package charNorm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadHTMLTableDefaultSelector(t *testing.T) {
+	html := `<html><body><table>
+		<tr><td>00</td><td>01</td></tr>
+		<tr><td>A</td><td>B</td></tr>
+		<tr><td>À</td><td>Ɓ</td></tr>
+		<tr><td>Á</td><td>ƀ</td></tr>
+	</table></body></html>`
+
+	table, stats, err := LoadHTMLTable(strings.NewReader(html), DefaultTableSelector)
+	if err != nil {
+		t.Fatalf("LoadHTMLTable: %v", err)
+	}
+	if got, want := table['A'], []rune("ÀÁ"); !runesEqual(got, want) {
+		t.Errorf("table['A'] = %q, want %q", string(got), string(want))
+	}
+	if got, want := table['B'], []rune("Ɓƀ"); !runesEqual(got, want) {
+		t.Errorf("table['B'] = %q, want %q", string(got), string(want))
+	}
+	if stats.DataRowsFound != 2 {
+		t.Errorf("stats.DataRowsFound = %d, want 2", stats.DataRowsFound)
+	}
+}
+
+func TestLoadHTMLTableDefaultSelectorIgnoresTrailingFootnoteRow(t *testing.T) {
+	// A table with exactly the rows the default selector expects (header +
+	// 60 data rows) plus one trailing non-data row (e.g. a footnote/legend).
+	// That trailing row must NOT be folded into the normalization map.
+	var b strings.Builder
+	b.WriteString("<html><body><table>\n<tr><td>00</td><td>01</td></tr>\n<tr><td>A</td><td>B</td></tr>\n")
+	for i := 0; i < 60; i++ {
+		b.WriteString("<tr><td>À</td><td>Ɓ</td></tr>\n")
+	}
+	b.WriteString("<tr><td>Notes: see appendix</td><td>ignore me</td></tr>\n")
+	b.WriteString("</table></body></html>")
+
+	table, stats, err := LoadHTMLTable(strings.NewReader(b.String()), DefaultTableSelector)
+	if err != nil {
+		t.Fatalf("LoadHTMLTable: %v", err)
+	}
+	for _, r := range table['A'] {
+		if r == 'N' {
+			t.Fatalf("table['A'] = %q contains footnote text, default selector should stop at row 61", string(table['A']))
+		}
+	}
+	for _, r := range table['B'] {
+		if r == 'i' {
+			t.Fatalf("table['B'] = %q contains footnote text, default selector should stop at row 61", string(table['B']))
+		}
+	}
+	if stats.DataRowsFound != 60 {
+		t.Errorf("stats.DataRowsFound = %d, want 60 (footnote row excluded)", stats.DataRowsFound)
+	}
+}
+
+func TestLoadHTMLTableShortTableIsNotAnError(t *testing.T) {
+	html := `<html><table>
+		<tr><td>00</td></tr>
+		<tr><td>A</td></tr>
+		<tr><td>À</td></tr>
+	</table></html>`
+
+	table, stats, err := LoadHTMLTable(strings.NewReader(html), DefaultTableSelector)
+	if err != nil {
+		t.Fatalf("LoadHTMLTable on a short table should not error, got: %v", err)
+	}
+	if got, want := table['A'], []rune("À"); !runesEqual(got, want) {
+		t.Errorf("table['A'] = %q, want %q", string(got), string(want))
+	}
+	if stats.DataRowsFound != 1 {
+		t.Errorf("stats.DataRowsFound = %d, want 1", stats.DataRowsFound)
+	}
+}
+
+func TestLoadHTMLTableColspanRowspan(t *testing.T) {
+	html := `<html><table>
+		<tr><td>00</td><td>01</td></tr>
+		<tr><td>A</td><td>B</td></tr>
+		<tr><td rowspan="2">À</td><td>Ɓ</td></tr>
+		<tr><td>ƀ</td></tr>
+	</table></html>`
+
+	table, _, err := LoadHTMLTable(strings.NewReader(html), DefaultTableSelector)
+	if err != nil {
+		t.Fatalf("LoadHTMLTable: %v", err)
+	}
+	if got, want := table['A'], []rune("À"); !runesEqual(got, want) {
+		t.Errorf("table['A'] = %q, want %q", string(got), string(want))
+	}
+	if got, want := table['B'], []rune("Ɓƀ"); !runesEqual(got, want) {
+		t.Errorf("table['B'] = %q, want %q", string(got), string(want))
+	}
+}
+
+func TestLoadHTMLTableSkipsNestedTable(t *testing.T) {
+	html := `<html><table>
+		<tr><td>00</td></tr>
+		<tr><td>A</td></tr>
+		<tr><td>À<table><tr><td>nested</td></tr></table></td></tr>
+	</table></html>`
+
+	table, stats, err := LoadHTMLTable(strings.NewReader(html), DefaultTableSelector)
+	if err != nil {
+		t.Fatalf("LoadHTMLTable: %v", err)
+	}
+	if stats.TablesInDocument != 2 {
+		t.Errorf("stats.TablesInDocument = %d, want 2", stats.TablesInDocument)
+	}
+	for _, r := range table['A'] {
+		if r == 'n' {
+			t.Fatalf("table['A'] = %q leaked text from the nested table", string(table['A']))
+		}
+	}
+}
+
+func TestLoadHTMLTableCellWithOnlyNestedTableTextIsEmpty(t *testing.T) {
+	// Unlike TestLoadHTMLTableSkipsNestedTable, this cell has no text of its
+	// own before the nested table, so if extractText ever descended into
+	// the nested table, its text ("Z") would itself become the cell's
+	// value and get parsed as real normalization data.
+	html := `<html><table>
+		<tr><td>00</td></tr>
+		<tr><td>A</td></tr>
+		<tr><td><table><tr><td>Z</td></tr></table></td></tr>
+	</table></html>`
+
+	table, stats, err := LoadHTMLTable(strings.NewReader(html), DefaultTableSelector)
+	if err != nil {
+		t.Fatalf("LoadHTMLTable: %v", err)
+	}
+	if len(table['A']) != 0 {
+		t.Fatalf("table['A'] = %q, want empty: a cell containing only a nested table should contribute no text", string(table['A']))
+	}
+	if stats.DataRowsFound != 1 {
+		t.Errorf("stats.DataRowsFound = %d, want 1", stats.DataRowsFound)
+	}
+}
+
+func TestLoadHTMLTableTransposed(t *testing.T) {
+	html := `<html><table>
+		<tr><td>A</td><td>À</td><td>Á</td></tr>
+		<tr><td>B</td><td>Ɓ</td><td>ƀ</td></tr>
+	</table></html>`
+
+	sel := TableSelector{HeaderRow: 0, DataRowRange: [2]int{1, -1}, Transposed: true}
+	table, _, err := LoadHTMLTable(strings.NewReader(html), sel)
+	if err != nil {
+		t.Fatalf("LoadHTMLTable: %v", err)
+	}
+	if got, want := table['A'], []rune("ÀÁ"); !runesEqual(got, want) {
+		t.Errorf("table['A'] = %q, want %q", string(got), string(want))
+	}
+	if got, want := table['B'], []rune("Ɓƀ"); !runesEqual(got, want) {
+		t.Errorf("table['B'] = %q, want %q", string(got), string(want))
+	}
+}
+
+func TestLoadHTMLTableSelectorByID(t *testing.T) {
+	html := `<html>
+		<table id="decoy"><tr><td>00</td></tr><tr><td>Z</td></tr><tr><td>Z</td></tr></table>
+		<table id="target"><tr><td>00</td></tr><tr><td>A</td></tr><tr><td>À</td></tr></table>
+	</html>`
+
+	sel := TableSelector{ID: "target", HeaderRow: 1, DataRowRange: [2]int{2, -1}}
+	table, _, err := LoadHTMLTable(strings.NewReader(html), sel)
+	if err != nil {
+		t.Fatalf("LoadHTMLTable: %v", err)
+	}
+	if got, want := table['A'], []rune("À"); !runesEqual(got, want) {
+		t.Errorf("table['A'] = %q, want %q", string(got), string(want))
+	}
+}
+
+func TestLoadHTMLTableNoTableFound(t *testing.T) {
+	if _, _, err := LoadHTMLTable(strings.NewReader("<html><body>no tables here</body></html>"), DefaultTableSelector); err == nil {
+		t.Fatal("expected error when document has no table")
+	}
+}
@@ -0,0 +1,353 @@
+package charNorm
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Source supplies the raw bytes of a normalization table, along with a
+// stable name used for cache keys and error messages. Implementations may
+// fetch the data from the network, disk, memory, or any other origin.
+type Source interface {
+	// Open returns a reader over the raw table data (HTML, JSON, or the
+	// line-based text format). The caller is responsible for closing it.
+	Open() (io.ReadCloser, error)
+
+	// Name returns a stable, human-readable identifier for the source
+	// (e.g. a URL or file path), used in cache keys and error messages.
+	Name() string
+}
+
+// HTTPSource fetches a normalization table over HTTP(S). When CacheDir is
+// set, successfully fetched bodies are cached on disk and verified with a
+// SHA-256 checksum on subsequent loads, so repeated runs (and air-gapped
+// environments, once primed) don't need network access.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client // defaults to http.DefaultClient
+
+	// CacheDir, if non-empty, enables on-disk caching of the fetched body.
+	CacheDir string
+	// CacheTTL controls how long a cached copy is considered fresh. Zero
+	// means the cached copy never expires once written.
+	CacheTTL time.Duration
+	// Version, if set, is mixed into the cache key alongside URL, so
+	// multiple versions of the same table can be cached side by side (e.g.
+	// "2024-01" vs "2024-06") and callers can pin a specific one. Bumping
+	// Version also forces a fresh fetch even if an older version's cache
+	// entry hasn't hit CacheTTL yet.
+	Version string
+}
+
+func (s *HTTPSource) Name() string { return s.URL }
+
+func (s *HTTPSource) Open() (io.ReadCloser, error) {
+	if s.URL == "" {
+		return nil, errors.New("url cannot be empty")
+	}
+
+	if s.CacheDir != "" {
+		if data, ok := s.readCache(); ok {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL %s: %w", s.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch URL %s: status code %d", s.URL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body from %s: %w", s.URL, err)
+	}
+
+	if s.CacheDir != "" {
+		if err := s.writeCache(body); err != nil {
+			// Caching is best-effort; a write failure shouldn't fail the load.
+			fmt.Fprintf(os.Stderr, "charNorm: warning: failed to cache %s: %v\n", s.URL, err)
+		}
+	}
+
+	return io.NopCloser(bytes.NewReader(body)), nil
+}
+
+func (s *HTTPSource) cacheKey() string {
+	sum := sha256.Sum256([]byte(s.Version + "\x00" + s.URL))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *HTTPSource) cachePaths() (dataPath, sumPath string) {
+	key := s.cacheKey()
+	return filepath.Join(s.CacheDir, key+".dat"), filepath.Join(s.CacheDir, key+".sha256")
+}
+
+func (s *HTTPSource) readCache() ([]byte, bool) {
+	dataPath, sumPath := s.cachePaths()
+
+	info, err := os.Stat(dataPath)
+	if err != nil {
+		return nil, false
+	}
+	if s.CacheTTL > 0 && time.Since(info.ModTime()) > s.CacheTTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if err != nil {
+		return nil, false
+	}
+
+	wantSum, err := os.ReadFile(sumPath)
+	if err != nil {
+		return nil, false
+	}
+	gotSum := sha256.Sum256(data)
+	if hex.EncodeToString(gotSum[:]) != strings.TrimSpace(string(wantSum)) {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (s *HTTPSource) writeCache(data []byte) error {
+	if err := os.MkdirAll(s.CacheDir, 0o755); err != nil {
+		return err
+	}
+	dataPath, sumPath := s.cachePaths()
+	if err := os.WriteFile(dataPath, data, 0o644); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	return os.WriteFile(sumPath, []byte(hex.EncodeToString(sum[:])), 0o644)
+}
+
+// FileSource reads a normalization table from a local file on disk.
+type FileSource struct {
+	Path string
+}
+
+func (s *FileSource) Name() string { return s.Path }
+
+func (s *FileSource) Open() (io.ReadCloser, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", s.Path, err)
+	}
+	return f, nil
+}
+
+// ReaderSource adapts an already-open io.Reader (e.g. an in-memory buffer
+// in a test) into a Source. Closing the returned ReadCloser is a no-op if R
+// does not itself implement io.Closer.
+type ReaderSource struct {
+	R          io.Reader
+	SourceName string
+}
+
+func (s *ReaderSource) Name() string {
+	if s.SourceName != "" {
+		return s.SourceName
+	}
+	return "reader"
+}
+
+func (s *ReaderSource) Open() (io.ReadCloser, error) {
+	if rc, ok := s.R.(io.ReadCloser); ok {
+		return rc, nil
+	}
+	return io.NopCloser(s.R), nil
+}
+
+// EmbeddedSource serves a normalization table from an in-memory byte slice,
+// typically backed by a go:embed directive, so a binary can ship with a
+// built-in table that requires neither network nor filesystem access.
+type EmbeddedSource struct {
+	Data       []byte
+	SourceName string
+}
+
+func (s *EmbeddedSource) Name() string {
+	if s.SourceName != "" {
+		return s.SourceName
+	}
+	return "embedded"
+}
+
+func (s *EmbeddedSource) Open() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.Data)), nil
+}
+
+// LoadNormalizationTable reads src, sniffs its format (HTML, the line-based
+// text format, or JSON), and parses it into a map of ASCII characters to
+// their Unicode normalizations.
+func LoadNormalizationTable(src Source) (map[rune][]rune, error) {
+	if src == nil {
+		return nil, errors.New("source cannot be nil")
+	}
+
+	rc, err := src.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source %s: %w", src.Name(), err)
+	}
+
+	switch sniffFormat(data) {
+	case formatJSON:
+		return parseJSONTable(data)
+	case formatText:
+		return parseTextTable(data)
+	default:
+		table, err := parseHTMLTable(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", src.Name(), err)
+		}
+		return table, nil
+	}
+}
+
+type tableFormat int
+
+const (
+	formatHTML tableFormat = iota
+	formatText
+	formatJSON
+)
+
+// sniffFormat guesses the encoding of a normalization table by inspecting
+// its first non-blank line.
+func sniffFormat(data []byte) tableFormat {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return formatHTML
+	}
+	switch trimmed[0] {
+	case '{', '[':
+		return formatJSON
+	case '<':
+		return formatHTML
+	}
+
+	firstLine := trimmed
+	if idx := bytes.IndexByte(trimmed, '\n'); idx >= 0 {
+		firstLine = trimmed[:idx]
+	}
+	if bytes.ContainsRune(firstLine, '\t') && !bytes.ContainsRune(firstLine, '<') {
+		return formatText
+	}
+	return formatHTML
+}
+
+// parseTextTable parses the simple line-based format:
+//
+//	A\tÀÁÂÃÄÅĀĂĄ
+//	B\tƁƂƃ
+//
+// Each line has the original ASCII character, a tab, and the set of Unicode
+// normalizations for it as a run of runes (no separator between them).
+// Blank lines and lines starting with '#' are ignored.
+func parseTextTable(data []byte) (map[rune][]rune, error) {
+	normalizationMap := make(map[rune][]rune)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"<char>\\t<variants>\", got %q", lineNo, line)
+		}
+
+		key := []rune(strings.TrimSpace(parts[0]))
+		if len(key) != 1 {
+			return nil, fmt.Errorf("line %d: expected a single original character, got %q", lineNo, parts[0])
+		}
+		original := key[0]
+
+		variants := normalizationMap[original]
+		seen := make(map[rune]bool, len(variants))
+		for _, v := range variants {
+			seen[v] = true
+		}
+		for _, variant := range parts[1] {
+			if !seen[variant] {
+				variants = append(variants, variant)
+				seen[variant] = true
+			}
+		}
+		normalizationMap[original] = variants
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read text table: %w", err)
+	}
+
+	return normalizationMap, nil
+}
+
+// parseJSONTable parses a JSON object mapping each single-character ASCII
+// key to an array of single-character Unicode variant strings, e.g.:
+//
+//	{"A": ["À", "Á", "Â", "Ã"], "B": ["Ɓ", "Ƃ", "ƃ"]}
+func parseJSONTable(data []byte) (map[rune][]rune, error) {
+	var raw map[string][]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON table: %w", err)
+	}
+
+	normalizationMap := make(map[rune][]rune, len(raw))
+	for key, variantStrs := range raw {
+		keyRunes := []rune(key)
+		if len(keyRunes) != 1 {
+			return nil, fmt.Errorf("expected a single original character as key, got %q", key)
+		}
+		original := keyRunes[0]
+
+		variants := make([]rune, 0, len(variantStrs))
+		seen := make(map[rune]bool, len(variantStrs))
+		for _, vs := range variantStrs {
+			vRunes := []rune(vs)
+			if len(vRunes) != 1 {
+				return nil, fmt.Errorf("expected a single variant character, got %q for key %q", vs, key)
+			}
+			v := vRunes[0]
+			if !seen[v] {
+				variants = append(variants, v)
+				seen[v] = true
+			}
+		}
+		normalizationMap[original] = variants
+	}
+
+	return normalizationMap, nil
+}
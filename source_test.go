@@ -0,0 +1,142 @@
+package charNorm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSniffFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want tableFormat
+	}{
+		{"html", "<html><table></table></html>", formatHTML},
+		{"json object", `{"A": ["À"]}`, formatJSON},
+		{"json array", `[1,2,3]`, formatJSON},
+		{"text", "A\tÀÁÂ\nB\tƁƃ\n", formatText},
+		{"empty", "", formatHTML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sniffFormat([]byte(tt.data)); got != tt.want {
+				t.Errorf("sniffFormat(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadNormalizationTableText(t *testing.T) {
+	src := &ReaderSource{R: strings.NewReader("A\tÀÁ\nB\tƁƃ\n# comment\n\n"), SourceName: "test.txt"}
+	table, err := LoadNormalizationTable(src)
+	if err != nil {
+		t.Fatalf("LoadNormalizationTable: %v", err)
+	}
+	if got, want := table['A'], []rune("ÀÁ"); !runesEqual(got, want) {
+		t.Errorf("table['A'] = %q, want %q", string(got), string(want))
+	}
+	if got, want := table['B'], []rune("Ɓƃ"); !runesEqual(got, want) {
+		t.Errorf("table['B'] = %q, want %q", string(got), string(want))
+	}
+}
+
+func TestLoadNormalizationTableJSON(t *testing.T) {
+	src := &ReaderSource{R: strings.NewReader(`{"A": ["À", "Á"]}`)}
+	table, err := LoadNormalizationTable(src)
+	if err != nil {
+		t.Fatalf("LoadNormalizationTable: %v", err)
+	}
+	if got, want := table['A'], []rune("ÀÁ"); !runesEqual(got, want) {
+		t.Errorf("table['A'] = %q, want %q", string(got), string(want))
+	}
+}
+
+func TestLoadNormalizationTableJSONRejectsMultiRuneKey(t *testing.T) {
+	src := &ReaderSource{R: strings.NewReader(`{"AB": ["À"]}`)}
+	if _, err := LoadNormalizationTable(src); err == nil {
+		t.Fatal("expected error for multi-rune JSON key, got nil")
+	}
+}
+
+func TestFileSourceMissing(t *testing.T) {
+	src := &FileSource{Path: "/nonexistent/path/table.txt"}
+	if _, err := src.Open(); err == nil {
+		t.Fatal("expected error opening missing file, got nil")
+	}
+}
+
+func TestHTTPSourceCaching(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("A\tÀ\n"))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	src := &HTTPSource{URL: server.URL, CacheDir: cacheDir}
+
+	for i := 0; i < 3; i++ {
+		table, err := LoadNormalizationTable(src)
+		if err != nil {
+			t.Fatalf("LoadNormalizationTable: %v", err)
+		}
+		if got, want := table['A'], []rune("À"); !runesEqual(got, want) {
+			t.Errorf("table['A'] = %q, want %q", string(got), string(want))
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected exactly 1 HTTP request across repeated cached loads, got %d", requests)
+	}
+}
+
+func TestHTTPSourceVersionsCacheSeparately(t *testing.T) {
+	bodies := map[string]string{"v1": "A\tÀ\n", "v2": "A\tÁ\n"}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(bodies[r.URL.Query().Get("v")]))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	srcV1 := &HTTPSource{URL: server.URL + "?v=v1", CacheDir: cacheDir, Version: "v1"}
+	srcV2 := &HTTPSource{URL: server.URL + "?v=v2", CacheDir: cacheDir, Version: "v2"}
+
+	t1, err := LoadNormalizationTable(srcV1)
+	if err != nil {
+		t.Fatalf("LoadNormalizationTable(v1): %v", err)
+	}
+	t2, err := LoadNormalizationTable(srcV2)
+	if err != nil {
+		t.Fatalf("LoadNormalizationTable(v2): %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 HTTP requests for 2 distinct versions, got %d", requests)
+	}
+	if runesEqual(t1['A'], t2['A']) {
+		t.Errorf("expected v1 and v2 caches to hold different data, both got %q", string(t1['A']))
+	}
+
+	// Re-fetching v1 should hit the cache, not the server.
+	if _, err := LoadNormalizationTable(srcV1); err != nil {
+		t.Fatalf("LoadNormalizationTable(v1) again: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected v1 re-fetch to be served from cache, got %d total requests", requests)
+	}
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
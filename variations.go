@@ -0,0 +1,137 @@
+package charNorm
+
+import "context"
+
+// Options configures variation generation via GenerateVariationsIter.
+type Options struct {
+	// MaxVariations caps the total number of variations produced. Zero (the
+	// default) means unlimited.
+	MaxVariations int
+
+	// MaxPerRune caps how many substitution candidates (including the
+	// original rune itself) are considered for each input character,
+	// trimming the combinatorial fan-out for characters with many
+	// normalizations. Zero (the default) means unlimited.
+	MaxPerRune int
+
+	// Context, if non-nil, is checked between variations; generation stops
+	// as soon as it is done.
+	Context context.Context
+
+	// Seed, if non-zero, deterministically rotates the candidate order for
+	// each rune position so that repeated calls with the same seed visit
+	// variations in the same order, while different seeds yield different
+	// traversal orders. It does not affect which variations exist, only the
+	// order they're emitted in - useful for sampling a prefix of a large
+	// variation space without always favoring the same characters.
+	Seed uint64
+}
+
+// GenerateVariations returns every combination of input with each rune
+// optionally substituted by one of its normalizations in m. The result grows
+// combinatorially with the length of input and the number of variants per
+// rune (a 20-character input with 4 candidates per character - the original
+// plus 3 normalizations - is 4^20 ≈ 10^12 combinations), and it materializes
+// the full result in memory. For large
+// inputs, prefer GenerateVariationsIter, which streams results instead.
+func GenerateVariations(input string, m map[rune][]rune) []string {
+	var out []string
+	for v := range GenerateVariationsIter(input, m, Options{}) {
+		out = append(out, v)
+	}
+	return out
+}
+
+// GenerateVariationsIter lazily emits every combination of input with each
+// rune optionally substituted by one of its normalizations in m, without
+// materializing the full result in memory. The returned channel is closed
+// once generation completes, opts.MaxVariations is reached, or
+// opts.Context is done. Callers must drain the channel (or cancel
+// opts.Context) to avoid leaking the generating goroutine.
+func GenerateVariationsIter(input string, m map[rune][]rune, opts Options) <-chan string {
+	out := make(chan string)
+
+	go func() {
+		defer close(out)
+
+		ctx := opts.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		choices := buildRuneChoices([]rune(input), m, opts)
+		count := 0
+
+		var emit func(prefix []rune, idx int) bool
+		emit = func(prefix []rune, idx int) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			default:
+			}
+			if opts.MaxVariations > 0 && count >= opts.MaxVariations {
+				return false
+			}
+
+			if idx == len(choices) {
+				select {
+				case out <- string(prefix):
+					count++
+				case <-ctx.Done():
+					return false
+				}
+				return opts.MaxVariations == 0 || count < opts.MaxVariations
+			}
+
+			for _, c := range choices[idx] {
+				next := make([]rune, len(prefix)+1)
+				copy(next, prefix)
+				next[len(prefix)] = c
+				if !emit(next, idx+1) {
+					return false
+				}
+			}
+			return true
+		}
+
+		emit(nil, 0)
+	}()
+
+	return out
+}
+
+// buildRuneChoices computes, for each rune of input, the ordered list of
+// candidate runes (the original rune plus any normalizations from m) that
+// GenerateVariationsIter will substitute at that position.
+func buildRuneChoices(input []rune, m map[rune][]rune, opts Options) [][]rune {
+	choices := make([][]rune, len(input))
+	for i, r := range input {
+		candidates := append([]rune{r}, m[r]...)
+		if opts.MaxPerRune > 0 && len(candidates) > opts.MaxPerRune {
+			candidates = candidates[:opts.MaxPerRune]
+		}
+		if opts.Seed != 0 {
+			candidates = rotateCandidates(candidates, opts.Seed, i)
+		}
+		choices[i] = candidates
+	}
+	return choices
+}
+
+// rotateCandidates deterministically rotates candidates by an offset derived
+// from seed and position, so the same seed always produces the same
+// traversal order.
+func rotateCandidates(candidates []rune, seed uint64, position int) []rune {
+	if len(candidates) < 2 {
+		return candidates
+	}
+	offset := int((seed + uint64(position)*2654435761) % uint64(len(candidates)))
+	if offset == 0 {
+		return candidates
+	}
+	rotated := make([]rune, len(candidates))
+	for i := range candidates {
+		rotated[i] = candidates[(i+offset)%len(candidates)]
+	}
+	return rotated
+}
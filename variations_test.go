@@ -0,0 +1,98 @@
+package charNorm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGenerateVariations(t *testing.T) {
+	m := map[rune][]rune{'A': []rune("À")}
+	got := GenerateVariations("A", m)
+	want := []string{"A", "À"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("GenerateVariations(%q) = %v, want %v", "A", got, want)
+	}
+}
+
+func TestGenerateVariationsCombinatorial(t *testing.T) {
+	m := map[rune][]rune{'A': []rune("À"), 'B': []rune("Ɓƀ")}
+	got := GenerateVariations("AB", m)
+	if len(got) != 2*3 {
+		t.Fatalf("GenerateVariations(%q) returned %d variations, want %d", "AB", len(got), 6)
+	}
+}
+
+func TestGenerateVariationsIterMaxVariations(t *testing.T) {
+	m := map[rune][]rune{'A': []rune("ÀÁÂÃ")}
+	opts := Options{MaxVariations: 3}
+	var got []string
+	for v := range GenerateVariationsIter("AAAA", m, opts) {
+		got = append(got, v)
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d variations, want exactly 3 (MaxVariations)", len(got))
+	}
+}
+
+func TestGenerateVariationsIterMaxPerRune(t *testing.T) {
+	m := map[rune][]rune{'A': []rune("ÀÁÂÃ")}
+	opts := Options{MaxPerRune: 2} // original + 1 variant
+	var got []string
+	for v := range GenerateVariationsIter("A", m, opts) {
+		got = append(got, v)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %d variations, want 2 (MaxPerRune caps candidates to 2)", len(got))
+	}
+}
+
+func TestGenerateVariationsIterContextCancellation(t *testing.T) {
+	m := map[rune][]rune{'A': []rune("ÀÁÂÃ"), 'B': []rune("Ɓƀƃƅ")}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count := 0
+	for v := range GenerateVariationsIter("AAAAAAAAAAAAAAAAAAAA", m, Options{Context: ctx}) {
+		_ = v
+		count++
+		if count == 5 {
+			cancel()
+		}
+	}
+
+	if count > 20 {
+		t.Errorf("expected generation to stop shortly after cancel, got %d variations", count)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context was never observed as done")
+	}
+}
+
+func TestGenerateVariationsIterDeterministicSeed(t *testing.T) {
+	m := map[rune][]rune{'A': []rune("ÀÁÂÃ")}
+	opts := Options{MaxVariations: 2, Seed: 42}
+
+	first := collect(GenerateVariationsIter("A", m, opts))
+	second := collect(GenerateVariationsIter("A", m, opts))
+
+	if len(first) != len(second) {
+		t.Fatalf("result length differs between runs with the same seed: %d vs %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("same seed produced different order at index %d: %q vs %q", i, first[i], second[i])
+		}
+	}
+}
+
+func collect(ch <-chan string) []string {
+	var out []string
+	for v := range ch {
+		out = append(out, v)
+	}
+	return out
+}